@@ -0,0 +1,32 @@
+package logger
+
+import (
+	"strings"
+
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// Colorful trace formats, built from the same ANSI constants
+// gorm.io/gorm/logger's default logger uses, so dev-time output looks the
+// same whichever logger is plugged into GORM.
+const (
+	colorTraceStr     = gormLogger.Green + "%s" + gormLogger.Reset + "\n" + gormLogger.Yellow + "[%.3fms] " + gormLogger.BlueBold + "[rows:%s]" + gormLogger.Reset
+	colorTraceWarnStr = gormLogger.Green + "%s " + gormLogger.Yellow + "%s" + gormLogger.Reset + "\n" + gormLogger.RedBold + "[%.3fms] " + gormLogger.Yellow + "[rows:%s]" + gormLogger.Reset
+	colorTraceErrStr  = gormLogger.RedBold + "%s" + gormLogger.Reset + "\n" + gormLogger.Yellow + "[%.3fms] " + gormLogger.BlueBold + "[rows:%s]" + gormLogger.Reset
+)
+
+// writerAdapter lets a gorm.io/gorm/logger.Writer (a single Printf method)
+// stand in for an io.Writer, so a logrus.Logger can be pointed at it.
+type writerAdapter struct {
+	w gormLogger.Writer
+}
+
+// Write forwards p to the wrapped Writer's Printf. Writer implementations
+// such as the standard library's *log.Logger append their own trailing
+// newline, so the one logrus already terminated p with is stripped first
+// to avoid a blank line after every entry.
+func (a *writerAdapter) Write(p []byte) (int, error) {
+	a.w.Printf("%s", strings.TrimSuffix(string(p), "\n"))
+
+	return len(p), nil
+}
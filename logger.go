@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 	"gorm.io/gorm/utils"
@@ -24,15 +25,81 @@ type Config struct {
 	SkipErrRecordNotFound bool
 	SourceField	string
 	ModuleName	string
+
+	// StructuredTrace makes Trace emit the SQL, elapsed time, row count and
+	// slow-query flag as distinct logrus.Fields instead of folding them into
+	// the printf-style trace message. Field names can be overridden via
+	// SQLField, DurationMsField, RowsField and SlowField; unset ones fall
+	// back to the defaults below.
+	StructuredTrace bool
+	SQLField	string
+	DurationMsField string
+	RowsField	string
+	SlowField	string
+
+	// ContextExtractor pulls per-request fields (e.g. request_id, user_id,
+	// trace_id) out of the context that GORM was invoked with, so they get
+	// attached to every Info/Warn/Error/Trace entry for that call. It is
+	// called once per call and may return nil.
+	ContextExtractor func(context.Context) logrus.Fields
+
+	// EnableOTel attaches SQL trace data to the OpenTelemetry span active in
+	// ctx as span events/attributes. If Tracer is also set, Trace starts a
+	// short-lived child span per query instead of reusing the active one.
+	EnableOTel bool
+	Tracer	trace.Tracer
+
+	// SlowQueryHandler, when set, is invoked from Trace for every query
+	// whose elapsed time exceeds SlowThreshold, whether or not a warn-level
+	// line is logged for it. It is not invoked while LogLevel is Silent,
+	// since Trace then returns before looking at the query.
+	SlowQueryHandler SlowQueryHandler
+
+	// Sanitizer rewrites the SQL statement before Trace logs it or records
+	// it on a span, e.g. to strip literals that might carry PII.
+	// SlowQueryHandler is given the statement unsanitized. RedactParams enables
+	// DefaultSanitizer when no custom Sanitizer is set. MaxSQLLength
+	// truncates the (sanitized) statement, appending "...", once it's set
+	// and exceeded.
+	Sanitizer	func(sql string) string
+	RedactParams	bool
+	MaxSQLLength	int
+
+	// Colorful switches the printf-style trace output (StructuredTrace
+	// false) to the ANSI-colored format used by gorm.io/gorm/logger's
+	// default logger, for parity with its dev-time console output.
+	Colorful bool
 }
 
+const (
+	defaultSQLField        = "sql"
+	defaultDurationMsField = "duration_ms"
+	defaultRowsField       = "rows"
+	defaultSlowField       = "slow"
+)
+
 type Logger struct {
 	log	*logrus.Logger
 	config Config
 }
 
 func (l* Logger) createEntry(ctx context.Context) *logrus.Entry {
-	return l.log.WithContext(ctx).WithField("module", l.config.ModuleName)
+	entry := l.log.WithContext(ctx).WithField("module", l.config.ModuleName)
+	if l.config.ContextExtractor != nil {
+		if fields := l.config.ContextExtractor(ctx); fields != nil {
+			entry = entry.WithFields(fields)
+		}
+	}
+
+	return entry
+}
+
+// FromContext returns a logrus.Entry carrying the module field and, when a
+// Config.ContextExtractor is configured, the per-request fields it extracts
+// from ctx. It lets callers log alongside GORM with the same correlation
+// fields that Trace/Info/Warn/Error attach to SQL logs.
+func (l *Logger) FromContext(ctx context.Context) *logrus.Entry {
+	return l.createEntry(ctx)
 }
 
 func (l *Logger) GetLogger() *logrus.Logger {
@@ -70,7 +137,8 @@ func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (string,
 	}
 
 	elapsed := time.Since(begin)
-	sql, rows := fc()
+	rawSQL, rows := fc()
+	sql := l.sanitizeSQL(rawSQL)
 	fields := logrus.Fields{}
 	if l.config.SourceField != "" {
 		fields[l.config.SourceField] = utils.FileWithLineNum()
@@ -82,21 +150,83 @@ func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (string,
 		rowsLog = "-"
 	}
 
+	isSlow := l.config.SlowThreshold != 0 && elapsed > l.config.SlowThreshold
+
+	if isSlow && l.config.SlowQueryHandler != nil {
+		l.config.SlowQueryHandler.OnSlowQuery(ctx, rawSQL, elapsed, rows)
+	}
+
+	if l.config.EnableOTel {
+		ctx = l.recordSpan(ctx, begin, sql, elapsed, rows, isSlow, err)
+	}
+
+	if l.config.StructuredTrace {
+		fields[l.fieldName(l.config.SQLField, defaultSQLField)] = sql
+		fields[l.fieldName(l.config.DurationMsField, defaultDurationMsField)] = elapsedMs
+		fields[l.fieldName(l.config.RowsField, defaultRowsField)] = rows
+		fields[l.fieldName(l.config.SlowField, defaultSlowField)] = isSlow
+	}
+
 	switch {
 	case err != nil && l.config.LogLevel >= gormLogger.Error && !(errors.Is(err, gorm.ErrRecordNotFound) && l.config.SkipErrRecordNotFound):
 		fields[logrus.ErrorKey] = err.Error()
-		l.createEntry(ctx).WithFields(fields).Errorf(traceErrStr, sql, elapsedMs, rowsLog)
-	case l.config.SlowThreshold != 0 && elapsed > l.config.SlowThreshold && l.config.LogLevel >= gormLogger.Warn:
-		slowLog := fmt.Sprintf("SLOW SQL >= %v", l.config.SlowThreshold)
-		l.createEntry(ctx).WithFields(fields).Warnf(traceWarnStr, sql, slowLog, elapsedMs, rowsLog)
+		entry := l.createEntry(ctx).WithFields(fields)
+		if l.config.StructuredTrace {
+			entry.Error(err.Error())
+		} else {
+			entry.Errorf(l.traceFormat(traceErrStr, colorTraceErrStr), sql, elapsedMs, rowsLog)
+		}
+	case isSlow && l.config.LogLevel >= gormLogger.Warn:
+		entry := l.createEntry(ctx).WithFields(fields)
+		if l.config.StructuredTrace {
+			entry.Warnf("SLOW SQL >= %v", l.config.SlowThreshold)
+		} else {
+			slowLog := fmt.Sprintf("SLOW SQL >= %v", l.config.SlowThreshold)
+			entry.Warnf(l.traceFormat(traceWarnStr, colorTraceWarnStr), sql, slowLog, elapsedMs, rowsLog)
+		}
 	case l.config.LogLevel == gormLogger.Info:
-		l.createEntry(ctx).WithFields(fields).Infof(traceStr, sql, elapsedMs, rowsLog)
+		entry := l.createEntry(ctx).WithFields(fields)
+		if l.config.StructuredTrace {
+			entry.Info("trace")
+		} else {
+			entry.Infof(l.traceFormat(traceStr, colorTraceStr), sql, elapsedMs, rowsLog)
+		}
 	default:
-		l.createEntry(ctx).WithFields(fields).Debugf(traceStr, sql, elapsedMs, rowsLog)
+		entry := l.createEntry(ctx).WithFields(fields)
+		if l.config.StructuredTrace {
+			entry.Debug("trace")
+		} else {
+			entry.Debugf(l.traceFormat(traceStr, colorTraceStr), sql, elapsedMs, rowsLog)
+		}
+	}
+}
+
+func (l *Logger) fieldName(override, fallback string) string {
+	if override != "" {
+		return override
+	}
+
+	return fallback
+}
+
+func (l *Logger) traceFormat(plain, colorful string) string {
+	if l.config.Colorful {
+		return colorful
 	}
+
+	return plain
 }
 
-func New(l *logrus.Logger, config Config) *Logger {
+// New builds a Logger that writes through l. l is typically the
+// application's *logrus.Logger, but anything satisfying the upstream
+// gorm.io/gorm/logger.Writer interface (a single Printf method, which
+// *logrus.Logger already implements) works here too. For a non-*logrus.Logger
+// l, New wraps it in a fresh default-configured *logrus.Logger (whose
+// TextFormatter is set to ForceColors when config.Colorful is set, since the
+// wrapped writer is not a terminal): the level and formatter l may already
+// have are not carried over, so GetLogger() and LogMode on the result
+// control only that fresh wrapper, not l itself.
+func New(l gormLogger.Writer, config Config) *Logger {
 	if config.ModuleName == "" {
 		config.ModuleName = "gorm"
 	}
@@ -105,8 +235,26 @@ func New(l *logrus.Logger, config Config) *Logger {
 		config.LogLevel = gormLogger.Info
 	}
 
+	log, ok := l.(*logrus.Logger)
+	if !ok {
+		log = logrus.New()
+		log.SetOutput(&writerAdapter{w: l})
+		if config.Colorful {
+			log.SetFormatter(&logrus.TextFormatter{ForceColors: true})
+		}
+	}
+
 	return &Logger{
-		log: l,
+		log: log,
 		config: config,
 	}
 }
+
+// NewFromWriter builds a Logger backed by writer, a gorm.io/gorm/logger.Writer
+// such as the standard library log.Logger the upstream default GORM logger
+// uses. It lets callers already set up for upstream's writer-based output
+// drop this package in without losing that destination, while still getting
+// logrus fanout (and, with Config.Colorful, its colored dev-time format).
+func NewFromWriter(writer gormLogger.Writer, config Config) *Logger {
+	return New(writer, config)
+}
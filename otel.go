@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// recordSpan attaches SQL trace data to an OpenTelemetry span. If
+// Config.Tracer is set, it starts a short-lived child span covering
+// [begin, begin+elapsed) and returns the ctx carrying it; otherwise it
+// records the same attributes on the span already active in ctx, if any.
+func (l *Logger) recordSpan(ctx context.Context, begin time.Time, sql string, elapsed time.Duration, rows int64, isSlow bool, err error) context.Context {
+	span := trace.SpanFromContext(ctx)
+	if l.config.Tracer != nil {
+		ctx, span = l.config.Tracer.Start(ctx, "gorm.query", trace.WithTimestamp(begin))
+		defer span.End(trace.WithTimestamp(begin.Add(elapsed)))
+	}
+
+	if !span.IsRecording() {
+		return ctx
+	}
+
+	span.SetAttributes(
+		attribute.String("db.system", "gorm"),
+		attribute.String("db.statement", sql),
+		attribute.Int64("db.rows_affected", rows),
+		attribute.Float64("db.duration_ms", float64(elapsed.Nanoseconds())/1e6),
+		attribute.Bool("db.slow", isSlow),
+	)
+
+	switch {
+	case err != nil && !(errors.Is(err, gorm.ErrRecordNotFound) && l.config.SkipErrRecordNotFound):
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case isSlow:
+		// OTel has no "warning" status; an event plus the db.slow attribute
+		// is the closest equivalent without miscategorizing a successful
+		// query as an error.
+		span.AddEvent("slow query", trace.WithAttributes(
+			attribute.Float64("db.duration_ms", float64(elapsed.Nanoseconds())/1e6),
+		))
+	}
+
+	return ctx
+}
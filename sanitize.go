@@ -0,0 +1,35 @@
+package logger
+
+import "regexp"
+
+// literalPattern matches single- and double-quoted string literals and
+// bare numeric literals, the parts of a SQL statement most likely to carry
+// emails, tokens or primary keys.
+var literalPattern = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"|\b\d+\.?\d*\b`)
+
+// DefaultSanitizer is the built-in Config.Sanitizer: it replaces every
+// string and numeric literal in sql with "?", leaving the statement's
+// shape intact without leaking the values bound to it.
+func DefaultSanitizer(sql string) string {
+	return literalPattern.ReplaceAllString(sql, "?")
+}
+
+// sanitizeSQL applies Config.Sanitizer (falling back to DefaultSanitizer
+// when Config.RedactParams is set but no custom Sanitizer was given) and
+// then truncates the result to Config.MaxSQLLength, if configured.
+func (l *Logger) sanitizeSQL(sql string) string {
+	sanitizer := l.config.Sanitizer
+	if sanitizer == nil && l.config.RedactParams {
+		sanitizer = DefaultSanitizer
+	}
+
+	if sanitizer != nil {
+		sql = sanitizer(sql)
+	}
+
+	if l.config.MaxSQLLength > 0 && len(sql) > l.config.MaxSQLLength {
+		sql = sql[:l.config.MaxSQLLength] + "..."
+	}
+
+	return sql
+}
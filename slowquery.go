@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// SlowQueryHandler is invoked from Trace whenever a query's elapsed time
+// exceeds Config.SlowThreshold and Config.LogLevel is not Silent, whether or
+// not a warn-level line is logged for the query. It lets callers wire slow queries into alerting, metrics or deeper
+// diagnostics without changing Trace itself.
+type SlowQueryHandler interface {
+	OnSlowQuery(ctx context.Context, sql string, elapsed time.Duration, rows int64)
+}
+
+// SlowQuery is a single recorded slow-query occurrence, as kept by
+// RingBufferHandler.
+type SlowQuery struct {
+	SQL     string
+	Elapsed time.Duration
+	Rows    int64
+	At      time.Time
+}
+
+// RingBufferHandler is a SlowQueryHandler that keeps the last N slow
+// queries in memory, for exposing on a /debug endpoint. It is safe for
+// concurrent use.
+type RingBufferHandler struct {
+	mu      sync.Mutex
+	entries []SlowQuery
+	next    int
+	full    bool
+}
+
+// NewRingBufferHandler returns a RingBufferHandler that retains up to size
+// slow queries, discarding the oldest once full. size <= 0 yields a handler
+// that retains nothing rather than one that panics on the first slow query.
+func NewRingBufferHandler(size int) *RingBufferHandler {
+	if size < 0 {
+		size = 0
+	}
+
+	return &RingBufferHandler{
+		entries: make([]SlowQuery, size),
+	}
+}
+
+func (h *RingBufferHandler) OnSlowQuery(_ context.Context, sql string, elapsed time.Duration, rows int64) {
+	if len(h.entries) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = SlowQuery{SQL: sql, Elapsed: elapsed, Rows: rows, At: time.Now()}
+	h.next++
+	if h.next == len(h.entries) {
+		h.next = 0
+		h.full = true
+	}
+}
+
+// Entries returns the currently retained slow queries, oldest first.
+func (h *RingBufferHandler) Entries() []SlowQuery {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		out := make([]SlowQuery, h.next)
+		copy(out, h.entries[:h.next])
+
+		return out
+	}
+
+	out := make([]SlowQuery, len(h.entries))
+	copy(out, h.entries[h.next:])
+	copy(out[len(h.entries)-h.next:], h.entries[:h.next])
+
+	return out
+}
+
+// SlowQueries returns the slow queries recorded so far, when Config's
+// SlowQueryHandler is a *RingBufferHandler. It returns nil for any other
+// handler, including none at all.
+func (l *Logger) SlowQueries() []SlowQuery {
+	if h, ok := l.config.SlowQueryHandler.(*RingBufferHandler); ok {
+		return h.Entries()
+	}
+
+	return nil
+}
+
+// PrometheusHandler is a SlowQueryHandler that observes each slow query's
+// duration in a Prometheus histogram, labeled by a caller-supplied SQL
+// fingerprint (e.g. the statement with literals stripped) so cardinality
+// stays bounded.
+type PrometheusHandler struct {
+	histogram   *prometheus.HistogramVec
+	fingerprint func(sql string) string
+}
+
+// NewPrometheusHandler returns a PrometheusHandler that observes query
+// durations, in seconds, on histogram labeled by fingerprint(sql).
+// histogram must have a single label, conventionally named "query".
+func NewPrometheusHandler(histogram *prometheus.HistogramVec, fingerprint func(sql string) string) *PrometheusHandler {
+	return &PrometheusHandler{
+		histogram:   histogram,
+		fingerprint: fingerprint,
+	}
+}
+
+func (h *PrometheusHandler) OnSlowQuery(_ context.Context, sql string, elapsed time.Duration, _ int64) {
+	h.histogram.WithLabelValues(h.fingerprint(sql)).Observe(elapsed.Seconds())
+}
+
+// explainJob is a unit of work handed to ExplainHandler's background worker.
+type explainJob struct {
+	ctx context.Context
+	sql string
+}
+
+// ExplainHandler is a SlowQueryHandler that asynchronously runs EXPLAIN
+// against slow queries and logs the resulting plan, without blocking the
+// Trace call that observed the slow query. Queries submitted while the
+// internal channel is full are dropped.
+type ExplainHandler struct {
+	db  *gorm.DB
+	log *logrus.Logger
+	ch  chan explainJob
+}
+
+// NewExplainHandler returns an ExplainHandler that runs "EXPLAIN " + sql
+// against db for every slow query it's given, logging the plan through
+// log. bufferSize bounds how many pending EXPLAINs may queue up; once full,
+// further slow queries are dropped rather than applying backpressure to
+// Trace.
+func NewExplainHandler(db *gorm.DB, log *logrus.Logger, bufferSize int) *ExplainHandler {
+	h := &ExplainHandler{
+		db:  db,
+		log: log,
+		ch:  make(chan explainJob, bufferSize),
+	}
+
+	go h.run()
+
+	return h
+}
+
+func (h *ExplainHandler) OnSlowQuery(ctx context.Context, sql string, _ time.Duration, _ int64) {
+	select {
+	case h.ch <- explainJob{ctx: ctx, sql: sql}:
+	default:
+		h.log.WithField("module", "gorm").Warn("explain handler: buffer full, dropping slow query")
+	}
+}
+
+func (h *ExplainHandler) run() {
+	for job := range h.ch {
+		var plan []map[string]interface{}
+		if err := h.db.WithContext(job.ctx).Raw("EXPLAIN " + job.sql).Scan(&plan).Error; err != nil {
+			h.log.WithField("module", "gorm").WithError(err).Warn("explain handler: failed to run EXPLAIN")
+
+			continue
+		}
+
+		h.log.WithField("module", "gorm").WithField("sql", job.sql).WithField("plan", plan).Warn("slow query plan")
+	}
+}